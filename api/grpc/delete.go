@@ -0,0 +1,15 @@
+package grpc
+
+import "encoding/json"
+
+// Delete removes a deployed function from the gateway. It is used during an
+// "--atomic" rollback to remove a function that did not exist before this
+// deploy run.
+func Delete(gateway, name, token string) error {
+	body, err := json.Marshal(map[string]string{"functionName": name})
+	if err != nil {
+		return err
+	}
+
+	return doRequest("DELETE", gateway, "/system/functions", token, body, nil)
+}
@@ -0,0 +1,86 @@
+// Package grpc is the CLI's thin client for the OpenFx gateway's function
+// management API.
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/keti-openfx/openfx-cli/config"
+)
+
+// ErrFunctionNotFound is returned when the gateway has no function with the
+// requested name, as distinct from a transport, auth, or server error.
+var ErrFunctionNotFound = errors.New("function not found")
+
+// DeployConfig is the payload sent to the gateway to create or update a
+// function.
+type DeployConfig struct {
+	FxGateway    string
+	FunctionName string
+	Image        string
+	EnvVars      map[string]string
+	Labels       map[string]string
+	Annotations  map[string]string
+	Constraints  []string
+	Secrets      []string
+	Limits       *config.FunctionResources
+	Requests     *config.FunctionResources
+	MinReplicas  int32
+	MaxReplicas  int32
+	Update       bool
+	Replace      bool
+}
+
+// Deploy creates or updates a function on the gateway named by
+// cfg.FxGateway.
+func Deploy(cfg DeployConfig, token string) error {
+	method := http.MethodPost
+	if cfg.Update {
+		method = http.MethodPut
+	}
+
+	body, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	return doRequest(method, cfg.FxGateway, "/system/functions", token, body, nil)
+}
+
+func doRequest(method, gateway, path, token string, body []byte, out interface{}) error {
+	url := fmt.Sprintf("http://%s%s", gateway, path)
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrFunctionNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gateway returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,33 @@
+package grpc
+
+import "github.com/keti-openfx/openfx-cli/config"
+
+// FunctionSpec is a deployed function's spec, as needed to print a dry-run
+// plan or fully restore a previous version during an "--atomic" rollback.
+type FunctionSpec struct {
+	Image       string
+	EnvVars     map[string]string
+	Labels      map[string]string
+	Annotations map[string]string
+	Constraints []string
+	Secrets     []string
+	Limits      *config.FunctionResources
+	Requests    *config.FunctionResources
+	MinReplicas int32
+	MaxReplicas int32
+}
+
+// Inspect fetches the spec currently deployed on the gateway for the named
+// function. It returns ErrFunctionNotFound, checkable with errors.Is, when
+// no such function is deployed; any other error means the lookup itself
+// failed (unreachable gateway, auth failure, ...) and the caller must not
+// treat that the same as "not found".
+func Inspect(gateway, name, token string) (*FunctionSpec, error) {
+	var spec FunctionSpec
+
+	if err := doRequest("GET", gateway, "/system/function/"+name, token, nil, &spec); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
@@ -0,0 +1,78 @@
+// Package builder shells out to local tooling (docker, etc.) needed to
+// build and push function images.
+package builder
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ExecCommand runs command in dir and returns its combined stdout/stderr
+// output.
+func ExecCommand(dir string, command []string) (string, error) {
+	return ExecCommandWithEnv(dir, command, nil)
+}
+
+// ExecCommandWithEnv runs command in dir with extraEnv appended to the
+// process environment, returning its combined stdout/stderr output. It is
+// used to scope a command (e.g. "docker push") to a temporary
+// DOCKER_CONFIG directory instead of the user's persistent one.
+func ExecCommandWithEnv(dir string, command []string, extraEnv []string) (string, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	if len(extraEnv) > 0 {
+		cmd.Env = append(os.Environ(), extraEnv...)
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// ExecCommandStdinWithEnv runs command in dir with extraEnv appended to the
+// process environment and stdin written to the command's standard input,
+// returning its combined stdout/stderr output. It is used for commands such
+// as "docker login --password-stdin" that must not receive secrets via
+// argv.
+func ExecCommandStdinWithEnv(dir string, command []string, stdin string, extraEnv []string) (string, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	if len(extraEnv) > 0 {
+		cmd.Env = append(os.Environ(), extraEnv...)
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// ExecCommandPipe runs command in dir, streaming its stdout and stderr
+// directly to the given writers.
+func ExecCommandPipe(dir string, command []string, stdout, stderr io.Writer) error {
+	return ExecCommandPipeWithEnv(dir, command, stdout, stderr, nil)
+}
+
+// ExecCommandPipeWithEnv runs command in dir with extraEnv appended to the
+// process environment, streaming its stdout and stderr directly to the
+// given writers.
+func ExecCommandPipeWithEnv(dir string, command []string, stdout, stderr io.Writer, extraEnv []string) error {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if len(extraEnv) > 0 {
+		cmd.Env = append(os.Environ(), extraEnv...)
+	}
+
+	return cmd.Run()
+}
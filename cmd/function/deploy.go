@@ -6,6 +6,9 @@ import (
 	"io/ioutil"
 	logpkg "log"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/ghodss/yaml"
 	"github.com/keti-openfx/openfx-cli/api/grpc"
@@ -22,6 +25,13 @@ var (
 	registry      string
 	minreplicas   int32
 	maxreplicas   int32
+	concurrency   int
+	failFast      bool
+	pinDigest     bool
+	noPinDigest   bool
+	regcredSecret string
+	dryRun        bool
+	atomic        bool
 )
 
 func init() {
@@ -40,6 +50,13 @@ func init() {
 	deployCmd.Flags().BoolVarP(&deployVerbose, "deployverbose", "v", false, "Print function build log")
 	deployCmd.Flags().Int32Var(&minreplicas, "min", 1, "Minimum Replicas for Function")
 	deployCmd.Flags().Int32Var(&maxreplicas, "max", 1, "Maximum Replicas for Function")
+	deployCmd.Flags().IntVarP(&concurrency, "parallel", "p", 1, "Number of functions to push and deploy concurrently")
+	deployCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop on the first function error instead of continuing and reporting all errors")
+	deployCmd.Flags().BoolVar(&pinDigest, "pin-digest", true, "Resolve the pushed image to its content-addressable digest before deploying")
+	deployCmd.Flags().BoolVar(&noPinDigest, "no-pin-digest", false, "Deploy the mutable image tag as-is instead of resolving it to a digest")
+	deployCmd.Flags().StringVar(&regcredSecret, "regcred-secret", "regcred", "Name of the Kubernetes secret holding registry credentials")
+	deployCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Resolve and print the deploy plan without pushing images or deploying functions")
+	deployCmd.Flags().BoolVar(&atomic, "atomic", false, "Roll back already-deployed functions if any function in this run fails to deploy")
 	deployCmd.MarkFlagRequired("config")
 }
 
@@ -56,10 +73,25 @@ var deployCmd = &cobra.Command{
 	openfx-cli function deploy -f config.yml --registry 127.0.0.1:5000
 	openfx-cli function deploy -f config.yml -g 10.0.0.180:31113
 	openfx-cli function deploy -f config.yml --min 1 --max 5
+	openfx-cli function deploy -f config.yml --parallel 4
+	openfx-cli function deploy -f config.yml --parallel 4 --fail-fast
+	openfx-cli function deploy -f config.yml --no-pin-digest
+	openfx-cli function deploy -f config.yml --regcred-secret my-registry-secret
+	openfx-cli function deploy -f config.yml --dry-run
+	openfx-cli function deploy -f config.yml --set image=myrepo/handler:1.2.3 --set replicas.max=5
+	openfx-cli function deploy -f config.yml --values values-prod.yaml
+	openfx-cli function deploy -f config.yml --atomic
         `,
 	PreRunE: preRunDeploy,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := runDeploy(); err != nil {
+		var err error
+		if dryRun {
+			err = runDryRun()
+		} else {
+			err = runDeploy()
+		}
+
+		if err != nil {
 			fmt.Println(err.Error())
 		}
 
@@ -73,12 +105,16 @@ func preRunDeploy(cmd *cobra.Command, args []string) error {
 		return errors.New(`one of "--update" flag or "--replace" flag must be false\n`)
 	}
 
+	if noPinDigest {
+		pinDigest = false
+	}
+
 	var configURL string
 	if configFile == "" {
 		e := fmt.Sprintf("please provide a '-f' flag for function creation\n")
 		return errors.New(e)
 	} else {
-		if err := parseConfigFile(); err != nil {
+		if err := parseTemplatedConfigFile(); err != nil {
 			return err
 		}
 		configURL = fxServices.Openfx.FxGatewayURL
@@ -120,7 +156,7 @@ func deploy(gw string, function config.Function, update, replace bool, minreplic
 		Labels:       labelMap,
 		Annotations:  AnnoMap,
 		Constraints:  function.Constraints,
-		Secrets:      append(function.Secrets, "regcred"),
+		Secrets:      append(function.Secrets, regcredSecret),
 		Limits:       function.Limits,
 		Requests:     function.Requests,
 
@@ -140,36 +176,292 @@ func runDeploy() error {
 		return errors.New("")
 	}
 
-	for name, function := range fxServices.Functions {
+	workers := concurrency
+	if workers <= 0 {
+		workers = 1
+	}
 
-		function.Name = name
+	var snapshots map[string]*grpc.FunctionSpec
+	if atomic {
+		var err error
+		snapshots, err = snapshotFunctions(fxServices.Functions)
+		if err != nil {
+			return fmt.Errorf("--atomic: %w", err)
+		}
+	}
 
-		log.Info("Pushing: %s, Image: %s in Registry: %s ...\n", function.Name, function.Image, function.RegistryURL)
-		if deployVerbose {
-			err := builder.ExecCommandPipe("./", []string{"docker", "push", function.Image}, os.Stdout, os.Stderr)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err := builder.ExecCommand("./", []string{"docker", "push", function.Image})
-			if err != nil {
-				return err
+	type job struct {
+		name     string
+		function config.Function
+	}
+
+	jobs := make(chan job)
+	errs := make([]error, 0)
+	deployed := make([]string, 0, len(fxServices.Functions))
+	skipped := make([]string, 0)
+	var stateMu sync.Mutex
+	var wg sync.WaitGroup
+
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if failFast {
+					select {
+					case <-stop:
+						stateMu.Lock()
+						skipped = append(skipped, j.name)
+						stateMu.Unlock()
+						continue
+					default:
+					}
+				}
+
+				err := pushAndDeployFunc(j.name, j.function)
+
+				stateMu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", j.name, err))
+				} else {
+					deployed = append(deployed, j.name)
+				}
+				stateMu.Unlock()
+
+				if err != nil && failFast {
+					stopOnce.Do(func() { close(stop) })
+				}
 			}
+		}()
+	}
+
+	for name, function := range fxServices.Functions {
+		function.Name = name
+		jobs <- job{name: name, function: function}
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if len(skipped) > 0 {
+		errs = append(errs, fmt.Errorf("%d functions skipped after --fail-fast stopped the queue: %s", len(skipped), strings.Join(skipped, ", ")))
+	}
+
+	if atomic {
+		if rollbackErr := rollbackFunctions(gateway, deployed, snapshots, token); rollbackErr != nil {
+			errs = append(errs, rollbackErr)
 		}
+	}
 
-		log.Info("Deploying: %s ...\n", function.Name)
+	return combineErrors(errs)
+}
+
+// pushAndDeployFunc is a seam over pushAndDeployFunction so tests can drive
+// runDeploy's concurrency and fail-fast bookkeeping without shelling out to
+// docker or a real gateway.
+var pushAndDeployFunc = pushAndDeployFunction
 
-		//DEPLOY
-		if err := deploy(gateway, function, update, replace, minreplicas, maxreplicas, token); err != nil {
+// pushAndDeployFunction pushes the function's image and deploys it to the
+// gateway, prefixing all progress output with the function name so that
+// interleaved output from concurrent workers stays readable.
+func pushAndDeployFunction(name string, function config.Function) error {
+	dockerEnv, cleanup, err := prepareRegistryAuth(name, function)
+	if err != nil {
+		return fmt.Errorf("registry login failed: %w", err)
+	}
+	defer cleanup()
+
+	log.Info("[%s] Pushing image: %s in Registry: %s ...\n", name, function.Image, function.RegistryURL)
+	if deployVerbose {
+		err := builder.ExecCommandPipeWithEnv("./", []string{"docker", "push", function.Image}, os.Stdout, os.Stderr, dockerEnv)
+		if err != nil {
+			return err
+		}
+	} else {
+		_, err := builder.ExecCommandWithEnv("./", []string{"docker", "push", function.Image}, dockerEnv)
+		if err != nil {
 			return err
 		}
+	}
+
+	if pinDigest {
+		digest, err := resolveImageDigest(function.Image)
+		if err != nil {
+			return fmt.Errorf("unable to resolve digest for image %s: %w", function.Image, err)
+		}
+		function.Image = digest
+	}
+
+	log.Info("[%s] Deploying: %s ...\n", name, name)
 
-		log.Info("http trigger url: http://%s/function/%s \n", gateway, function.Name)
+	//DEPLOY
+	if err := deploy(gateway, function, update, replace, minreplicas, maxreplicas, token); err != nil {
+		return err
 	}
 
+	log.Info("[%s] http trigger url: http://%s/function/%s, image: %s \n", name, gateway, name, function.Image)
+
 	return nil
 }
 
+// prepareRegistryAuth authenticates against the registry hosting the
+// function's image before it is pushed, scoped to a temporary DOCKER_CONFIG
+// directory for this invocation only so it never touches the user's
+// persistent ~/.docker/config.json or leaves credentials behind. Callers
+// must pass the returned env to every docker command for this function (the
+// login is invisible to any other DOCKER_CONFIG) and must call the returned
+// cleanup func once done.
+//
+// Credentials are resolved, in precedence order, from the function's
+// "registryAuth" override, then the credential store populated by
+// "openfx-cli function login". The per-function override is resolved fully
+// (credential helper and static username/password together) before ever
+// consulting the store, so an explicit per-function override isn't partly
+// discarded in favor of a credHelper saved globally for that registry. A
+// credential helper takes priority over static credentials: it is written as
+// the "credsStore" of the scoped config, so docker asks the helper for
+// credentials itself. If nothing is configured, no login is performed, no
+// scoped config dir is used and the returned env is nil, so docker's own
+// (potentially already logged-in) configuration is used.
+func prepareRegistryAuth(name string, function config.Function) ([]string, func(), error) {
+	noop := func() {}
+
+	configDir, err := ioutil.TempDir("", "openfx-docker-config-")
+	if err != nil {
+		return nil, noop, err
+	}
+	cleanup := func() { os.RemoveAll(configDir) }
+	dockerEnv := []string{"DOCKER_CONFIG=" + configDir}
+
+	registryHost := parseRegistryHost(function.Image)
+
+	credHelper, username, secret, err := resolveRegistryCredentials(function, registryHost)
+	if err != nil {
+		cleanup()
+		return nil, noop, err
+	}
+
+	if credHelper != "" {
+		log.Info("[%s] Using credential helper %q for registry %s\n", name, credHelper, registryDisplayName(registryHost))
+		helperConfig := []byte(fmt.Sprintf("{\"credsStore\": %q}\n", credHelper))
+		if err := ioutil.WriteFile(filepath.Join(configDir, "config.json"), helperConfig, 0600); err != nil {
+			cleanup()
+			return nil, noop, err
+		}
+		return dockerEnv, cleanup, nil
+	}
+
+	if username == "" || secret == "" {
+		cleanup()
+		return nil, noop, nil
+	}
+
+	log.Info("[%s] Logging in to registry %s as %s ...\n", name, registryDisplayName(registryHost), username)
+
+	loginArgs := []string{"docker", "login", "--username", username, "--password-stdin"}
+	if registryHost != "" {
+		loginArgs = append(loginArgs, registryHost)
+	}
+
+	if _, err := builder.ExecCommandStdinWithEnv("./", loginArgs, secret, dockerEnv); err != nil {
+		cleanup()
+		return nil, noop, err
+	}
+
+	return dockerEnv, cleanup, nil
+}
+
+// resolveRegistryCredentials resolves the credential helper name and/or
+// username/password to use for registryHost, in precedence order: the
+// function's own "registryAuth" block, resolved fully on its own, then the
+// credential store populated by "openfx-cli function login". A per-function
+// override is never partly discarded in favor of a credHelper saved
+// globally for that registry - if the function specifies anything at all,
+// the store is not consulted.
+func resolveRegistryCredentials(function config.Function, registryHost string) (credHelper, username, secret string, err error) {
+	if function.RegistryAuth != nil {
+		credHelper = function.RegistryAuth.CredHelper
+		username = function.RegistryAuth.Username
+		secret = function.RegistryAuth.Password
+	}
+
+	if credHelper != "" || username != "" || secret != "" {
+		return credHelper, username, secret, nil
+	}
+
+	username, secret, credHelper, err = config.LookupRegistryAuth(registryHost)
+	return credHelper, username, secret, err
+}
+
+// parseRegistryHost extracts the registry host from a "host/repo:tag" image
+// reference, returning "" for unqualified images that resolve to Docker Hub.
+func parseRegistryHost(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	host := parts[0]
+	if strings.Contains(host, ".") || strings.Contains(host, ":") || host == "localhost" {
+		return host
+	}
+
+	return ""
+}
+
+func registryDisplayName(host string) string {
+	if host == "" {
+		return "docker.io"
+	}
+	return host
+}
+
+// resolveImageDigest inspects the locally pushed image and returns its
+// immutable "repo@sha256:..." reference so that redeploys can pin to the
+// exact content that was pushed rather than a mutable tag.
+func resolveImageDigest(image string) (string, error) {
+	out, err := builder.ExecCommand("./", []string{"docker", "inspect", "--format={{index .RepoDigests 0}}", image})
+	if err != nil {
+		return "", err
+	}
+
+	return parseDigestOutput(image, out)
+}
+
+// parseDigestOutput extracts the digest reference from "docker inspect"
+// output, split out from resolveImageDigest so the parsing can be unit
+// tested without shelling out to docker.
+func parseDigestOutput(image, out string) (string, error) {
+	digest := strings.TrimSpace(out)
+	if digest == "" || digest == "<no value>" {
+		return "", fmt.Errorf("no repo digest found for image %s", image)
+	}
+
+	return digest, nil
+}
+
+// combineErrors aggregates per-function deploy errors into a single error
+// so that callers of runDeploy see every failure instead of only the first.
+func combineErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Errorf("%d functions failed to deploy:\n%s", len(errs), strings.Join(messages, "\n"))
+}
+
 func readFiles(files []string) (map[string]string, error) {
 	envs := make(map[string]string)
 
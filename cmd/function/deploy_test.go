@@ -0,0 +1,162 @@
+package function
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/keti-openfx/openfx-cli/config"
+)
+
+func TestParseRegistryHost(t *testing.T) {
+	cases := []struct {
+		image string
+		want  string
+	}{
+		{"alexellis2/faas-url-ping:latest", ""},
+		{"nginx", ""},
+		{"127.0.0.1:5000/echo:latest", "127.0.0.1:5000"},
+		{"registry.example.com/team/echo:latest", "registry.example.com"},
+		{"localhost/echo:latest", "localhost"},
+		{"docker.io/library/nginx:latest", "docker.io"},
+	}
+
+	for _, c := range cases {
+		if got := parseRegistryHost(c.image); got != c.want {
+			t.Errorf("parseRegistryHost(%q) = %q, want %q", c.image, got, c.want)
+		}
+	}
+}
+
+func TestRegistryDisplayName(t *testing.T) {
+	if got := registryDisplayName(""); got != "docker.io" {
+		t.Errorf("registryDisplayName(\"\") = %q, want docker.io", got)
+	}
+	if got := registryDisplayName("127.0.0.1:5000"); got != "127.0.0.1:5000" {
+		t.Errorf("registryDisplayName(127.0.0.1:5000) = %q, want unchanged", got)
+	}
+}
+
+func TestRunDeployReportsSkippedFunctionsAfterFailFast(t *testing.T) {
+	origPush := pushAndDeployFunc
+	origFunctions := fxServices.Functions
+	origConcurrency := concurrency
+	origFailFast := failFast
+	origAtomic := atomic
+	defer func() {
+		pushAndDeployFunc = origPush
+		fxServices.Functions = origFunctions
+		concurrency = origConcurrency
+		failFast = origFailFast
+		atomic = origAtomic
+	}()
+
+	var mu sync.Mutex
+	called := map[string]bool{}
+	pushAndDeployFunc = func(name string, function config.Function) error {
+		mu.Lock()
+		called[name] = true
+		mu.Unlock()
+		return errors.New("deploy failed")
+	}
+
+	fxServices.Functions = map[string]config.Function{
+		"fn-a": {Image: "repo/fn-a:1"},
+		"fn-b": {Image: "repo/fn-b:1"},
+		"fn-c": {Image: "repo/fn-c:1"},
+	}
+	concurrency = 1
+	failFast = true
+	atomic = false
+
+	err := runDeploy()
+	if err == nil {
+		t.Fatal("runDeploy() = nil error, want error since every function fails")
+	}
+
+	if !strings.Contains(err.Error(), "skipped") {
+		t.Errorf("runDeploy() error = %q, want it to mention skipped functions", err.Error())
+	}
+
+	calledCount := 0
+	mu.Lock()
+	for range called {
+		calledCount++
+	}
+	mu.Unlock()
+
+	if calledCount >= len(fxServices.Functions) {
+		t.Errorf("pushAndDeployFunc was called for all %d functions, want --fail-fast to stop before the last one", len(fxServices.Functions))
+	}
+}
+
+func TestResolveRegistryCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := config.SaveRegistryAuth("registry.example.com", "", "", "stored-helper"); err != nil {
+		t.Fatalf("SaveRegistryAuth() = %v", err)
+	}
+
+	t.Run("per-function static credentials win over a stored credHelper", func(t *testing.T) {
+		function := config.Function{
+			RegistryAuth: &config.RegistryAuth{Username: "alice", Password: "s3cr3t"},
+		}
+
+		credHelper, username, secret, err := resolveRegistryCredentials(function, "registry.example.com")
+		if err != nil {
+			t.Fatalf("resolveRegistryCredentials() = %v", err)
+		}
+		if credHelper != "" {
+			t.Errorf("credHelper = %q, want empty - the per-function override should not fall through to the stored helper", credHelper)
+		}
+		if username != "alice" || secret != "s3cr3t" {
+			t.Errorf("username/secret = %q/%q, want the function's own alice/s3cr3t", username, secret)
+		}
+	})
+
+	t.Run("per-function credHelper is used as-is", func(t *testing.T) {
+		function := config.Function{
+			RegistryAuth: &config.RegistryAuth{CredHelper: "function-helper"},
+		}
+
+		credHelper, _, _, err := resolveRegistryCredentials(function, "registry.example.com")
+		if err != nil {
+			t.Fatalf("resolveRegistryCredentials() = %v", err)
+		}
+		if credHelper != "function-helper" {
+			t.Errorf("credHelper = %q, want function-helper", credHelper)
+		}
+	})
+
+	t.Run("falls back to the store when the function has no override at all", func(t *testing.T) {
+		credHelper, _, _, err := resolveRegistryCredentials(config.Function{}, "registry.example.com")
+		if err != nil {
+			t.Fatalf("resolveRegistryCredentials() = %v", err)
+		}
+		if credHelper != "stored-helper" {
+			t.Errorf("credHelper = %q, want the stored-helper fallback", credHelper)
+		}
+	})
+}
+
+func TestParseDigestOutput(t *testing.T) {
+	const image = "repo/fn:latest"
+
+	if _, err := parseDigestOutput(image, "\n"); err == nil {
+		t.Error("parseDigestOutput(empty output) = nil error, want error")
+	}
+
+	if _, err := parseDigestOutput(image, "<no value>\n"); err == nil {
+		t.Error(`parseDigestOutput("<no value>") = nil error, want error`)
+	}
+
+	want := "repo/fn@sha256:3f8a45b2c7d9e1f0a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3"
+	got, err := parseDigestOutput(image, want+"\n")
+	if err != nil {
+		t.Fatalf("parseDigestOutput(%q) returned error: %v", want, err)
+	}
+	if got != want {
+		t.Errorf("parseDigestOutput(%q) = %q, want %q", want, got, want)
+	}
+}
@@ -0,0 +1,24 @@
+package function
+
+import (
+	"github.com/keti-openfx/openfx-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	token      string
+	gateway    string
+	configFile string
+	fxServices config.Services
+)
+
+// FunctionCmd is the parent command for every "openfx-cli function ..."
+// subcommand.
+var FunctionCmd = &cobra.Command{
+	Use:   "function",
+	Short: "Manage OpenFx functions",
+}
+
+func init() {
+	FunctionCmd.AddCommand(deployCmd)
+}
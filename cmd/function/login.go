@@ -0,0 +1,71 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/keti-openfx/openfx-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loginRegistry   string
+	loginUsername   string
+	loginPassword   string
+	loginToken      string
+	loginCredHelper string
+)
+
+func init() {
+	loginCmd.Flags().StringVar(&loginRegistry, "registry", "", "Registry host to store credentials for (defaults to Docker Hub)")
+	loginCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "Registry username")
+	loginCmd.Flags().StringVarP(&loginPassword, "password", "p", "", "Registry password")
+	loginCmd.Flags().StringVar(&loginToken, "password-token", "", "Registry access token, stored instead of a password")
+	loginCmd.Flags().StringVar(&loginCredHelper, "cred-helper", "", "Name of a docker-credential-* helper binary to use instead of static credentials")
+
+	FunctionCmd.AddCommand(loginCmd)
+}
+
+var loginCmd = &cobra.Command{
+	Use:   `login`,
+	Short: `Store registry credentials used by "function deploy"`,
+	Long: `
+	Store a registry username/password (or access token), or the name of a docker credential helper, so that "function deploy" can authenticate "docker push" before pushing function images.
+	`,
+	Example: `
+	openfx-cli function login -u someuser -p secretpass
+	openfx-cli function login --registry 127.0.0.1:5000 -u admin --password-token $TOKEN
+	openfx-cli function login --registry 127.0.0.1:5000 --cred-helper ecr-login
+        `,
+	RunE: runLogin,
+}
+
+func runLogin(cmd *cobra.Command, args []string) error {
+	if loginCredHelper != "" {
+		if err := config.SaveRegistryAuth(loginRegistry, "", "", loginCredHelper); err != nil {
+			return err
+		}
+
+		fmt.Printf("credential helper %q saved for registry %q\n", loginCredHelper, registryDisplayName(loginRegistry))
+		return nil
+	}
+
+	if loginUsername == "" {
+		return errors.New("please provide a '--username' flag for function login\n")
+	}
+
+	secret := loginPassword
+	if secret == "" {
+		secret = loginToken
+	}
+	if secret == "" {
+		return errors.New("please provide either a '--password' or '--password-token' flag for function login\n")
+	}
+
+	if err := config.SaveRegistryAuth(loginRegistry, loginUsername, secret, ""); err != nil {
+		return err
+	}
+
+	fmt.Printf("credentials for registry %q saved\n", registryDisplayName(loginRegistry))
+	return nil
+}
@@ -0,0 +1,186 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keti-openfx/openfx-cli/api/grpc"
+	"github.com/keti-openfx/openfx-cli/config"
+)
+
+// secretEnvSubstrings marks env var names whose values are redacted in the
+// dry-run plan output, since there is no explicit secret flag on a single
+// environment variable.
+var secretEnvSubstrings = []string{"SECRET", "PASSWORD", "TOKEN", "KEY", "CREDENTIAL"}
+
+type functionPlan struct {
+	Name        string
+	Action      string
+	Image       string
+	DigestNote  string
+	Env         map[string]string
+	Labels      map[string]string
+	Annotations map[string]string
+	MinReplicas int32
+	MaxReplicas int32
+}
+
+// runDryRun resolves every function exactly as deploy() would, but performs
+// no "docker push" or grpc.Deploy call. It prints a plan classifying each
+// function as a create, update or no-change against the spec currently
+// running on the gateway, so large multi-function YAMLs can be validated
+// in CI without side effects.
+func runDryRun() error {
+	if len(fxServices.Functions) <= 0 {
+		return errors.New("")
+	}
+
+	names := make([]string, 0, len(fxServices.Functions))
+	for name := range fxServices.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		function := fxServices.Functions[name]
+		function.Name = name
+
+		plan, err := buildFunctionPlan(function)
+		if err != nil {
+			return err
+		}
+
+		printFunctionPlan(plan)
+	}
+
+	return nil
+}
+
+func buildFunctionPlan(function config.Function) (*functionPlan, error) {
+	fileEnvironment, err := readFiles(function.EnvironmentFile)
+	if err != nil {
+		return nil, err
+	}
+	allEnvironment := mergeMap(function.Environment, fileEnvironment)
+
+	labelMap := map[string]string{}
+	if function.Labels != nil {
+		labelMap = *function.Labels
+	}
+
+	annoMap := map[string]string{}
+	if function.Maintainer != "" {
+		annoMap["maintainer"] = function.Maintainer
+	}
+	if function.Description != "" {
+		annoMap["desc"] = function.Description
+	}
+
+	plan := &functionPlan{
+		Name:        function.Name,
+		Image:       function.Image,
+		Env:         allEnvironment,
+		Labels:      labelMap,
+		Annotations: annoMap,
+		MinReplicas: minreplicas,
+		MaxReplicas: maxreplicas,
+	}
+
+	// A real deploy with --pin-digest (the default) sends the resolved
+	// "repo@sha256:..." reference, not the mutable tag in the config, so
+	// the plan must compare against the same digest or every function
+	// will be misreported as "update" on every run.
+	if pinDigest {
+		if digest, err := resolveImageDigest(function.Image); err == nil {
+			plan.Image = digest
+		} else {
+			plan.DigestNote = fmt.Sprintf("--pin-digest is enabled but the digest for %q could not be resolved locally (%v); comparing against the mutable tag instead", function.Image, err)
+		}
+	}
+
+	current, err := grpc.Inspect(gateway, function.Name, token)
+	if err != nil {
+		if errors.Is(err, grpc.ErrFunctionNotFound) {
+			plan.Action = "create"
+			return plan, nil
+		}
+		return nil, fmt.Errorf("unable to fetch current spec for %s: %w", function.Name, err)
+	}
+
+	plan.Action = classifyChange(current, plan)
+	return plan, nil
+}
+
+func classifyChange(current *grpc.FunctionSpec, plan *functionPlan) string {
+	if current == nil {
+		return "create"
+	}
+
+	if current.Image == plan.Image &&
+		mapsEqual(current.EnvVars, plan.Env) &&
+		mapsEqual(current.Labels, plan.Labels) &&
+		mapsEqual(current.Annotations, plan.Annotations) &&
+		current.MinReplicas == plan.MinReplicas &&
+		current.MaxReplicas == plan.MaxReplicas {
+		return "no-change"
+	}
+
+	return "update"
+}
+
+func mapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func printFunctionPlan(plan *functionPlan) {
+	fmt.Printf("%s: %s\n", plan.Name, strings.ToUpper(plan.Action))
+	fmt.Printf("  image:       %s\n", plan.Image)
+	fmt.Printf("  min/max:     %d/%d\n", plan.MinReplicas, plan.MaxReplicas)
+	if plan.DigestNote != "" {
+		fmt.Printf("  note:        %s\n", plan.DigestNote)
+	}
+
+	fmt.Println("  env:")
+	for _, k := range sortedKeys(plan.Env) {
+		fmt.Printf("    %s=%s\n", k, redactIfSecret(k, plan.Env[k]))
+	}
+
+	fmt.Println("  labels:")
+	for _, k := range sortedKeys(plan.Labels) {
+		fmt.Printf("    %s=%s\n", k, plan.Labels[k])
+	}
+
+	fmt.Println("  annotations:")
+	for _, k := range sortedKeys(plan.Annotations) {
+		fmt.Printf("    %s=%s\n", k, plan.Annotations[k])
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func redactIfSecret(key, value string) string {
+	upper := strings.ToUpper(key)
+	for _, substr := range secretEnvSubstrings {
+		if strings.Contains(upper, substr) {
+			return "<redacted>"
+		}
+	}
+	return value
+}
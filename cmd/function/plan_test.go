@@ -0,0 +1,86 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/keti-openfx/openfx-cli/api/grpc"
+)
+
+func TestMapsEqual(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b map[string]string
+		want bool
+	}{
+		{"both nil", nil, nil, true},
+		{"one nil one empty", nil, map[string]string{}, true},
+		{"equal", map[string]string{"a": "1"}, map[string]string{"a": "1"}, true},
+		{"different value", map[string]string{"a": "1"}, map[string]string{"a": "2"}, false},
+		{"different length", map[string]string{"a": "1"}, map[string]string{"a": "1", "b": "2"}, false},
+	}
+
+	for _, c := range cases {
+		if got := mapsEqual(c.a, c.b); got != c.want {
+			t.Errorf("%s: mapsEqual(%v, %v) = %v, want %v", c.name, c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestClassifyChange(t *testing.T) {
+	plan := &functionPlan{
+		Image:       "repo/fn@sha256:aaa",
+		Env:         map[string]string{"FOO": "bar"},
+		Labels:      map[string]string{"l": "v"},
+		Annotations: map[string]string{"a": "v"},
+		MinReplicas: 1,
+		MaxReplicas: 3,
+	}
+
+	t.Run("no existing deployment is a create", func(t *testing.T) {
+		if got := classifyChange(nil, plan); got != "create" {
+			t.Errorf("classifyChange(nil, plan) = %q, want create", got)
+		}
+	})
+
+	t.Run("identical spec is a no-change", func(t *testing.T) {
+		current := &grpc.FunctionSpec{
+			Image:       plan.Image,
+			EnvVars:     plan.Env,
+			Labels:      plan.Labels,
+			Annotations: plan.Annotations,
+			MinReplicas: plan.MinReplicas,
+			MaxReplicas: plan.MaxReplicas,
+		}
+		if got := classifyChange(current, plan); got != "no-change" {
+			t.Errorf("classifyChange(current, plan) = %q, want no-change", got)
+		}
+	})
+
+	t.Run("different image is an update", func(t *testing.T) {
+		current := &grpc.FunctionSpec{
+			Image:       "repo/fn@sha256:bbb",
+			EnvVars:     plan.Env,
+			Labels:      plan.Labels,
+			Annotations: plan.Annotations,
+			MinReplicas: plan.MinReplicas,
+			MaxReplicas: plan.MaxReplicas,
+		}
+		if got := classifyChange(current, plan); got != "update" {
+			t.Errorf("classifyChange(current, plan) = %q, want update", got)
+		}
+	})
+
+	t.Run("different replica count is an update", func(t *testing.T) {
+		current := &grpc.FunctionSpec{
+			Image:       plan.Image,
+			EnvVars:     plan.Env,
+			Labels:      plan.Labels,
+			Annotations: plan.Annotations,
+			MinReplicas: plan.MinReplicas,
+			MaxReplicas: plan.MaxReplicas + 1,
+		}
+		if got := classifyChange(current, plan); got != "update" {
+			t.Errorf("classifyChange(current, plan) = %q, want update", got)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/keti-openfx/openfx-cli/api/grpc"
+	"github.com/keti-openfx/openfx-cli/cmd/log"
+	"github.com/keti-openfx/openfx-cli/config"
+)
+
+// inspectFunc, deployFunc, and deleteFunc are seams over the grpc package so
+// tests can stub the gateway response without a real network call.
+var (
+	inspectFunc = grpc.Inspect
+	deployFunc  = grpc.Deploy
+	deleteFunc  = grpc.Delete
+)
+
+// snapshotFunctions fetches the currently-deployed spec of every target
+// function before an "--atomic" deploy starts, so a failed run can be
+// reverted. A nil entry means the function did not exist yet and should be
+// deleted, rather than redeployed, on rollback. Only grpc.ErrFunctionNotFound
+// is treated as "didn't exist" - any other error (an unreachable gateway,
+// auth failure, ...) aborts the snapshot, since silently treating it the
+// same way could make rollback delete a function that actually exists.
+func snapshotFunctions(functions map[string]config.Function) (map[string]*grpc.FunctionSpec, error) {
+	snapshots := make(map[string]*grpc.FunctionSpec, len(functions))
+
+	for name := range functions {
+		spec, err := inspectFunc(gateway, name, token)
+		if err != nil {
+			if errors.Is(err, grpc.ErrFunctionNotFound) {
+				snapshots[name] = nil
+				continue
+			}
+			return nil, fmt.Errorf("unable to snapshot %s before an atomic deploy: %w", name, err)
+		}
+		snapshots[name] = spec
+	}
+
+	return snapshots, nil
+}
+
+// rollbackFunctions reverts every function named in deployed back to its
+// pre-deploy snapshot: functions that previously existed are redeployed with
+// their old spec, and functions that are new in this run are deleted.
+func rollbackFunctions(gw string, deployed []string, snapshots map[string]*grpc.FunctionSpec, token string) error {
+	var errs []error
+
+	for _, name := range deployed {
+		snapshot := snapshots[name]
+
+		if snapshot == nil {
+			log.Info("[%s] rolling back: deleting newly-created function ...\n", name)
+			if err := deleteFunc(gw, name, token); err != nil {
+				errs = append(errs, fmt.Errorf("rollback delete %s: %w", name, err))
+			}
+			continue
+		}
+
+		log.Info("[%s] rolling back to previous spec, image: %s ...\n", name, snapshot.Image)
+
+		rollbackConfig := grpc.DeployConfig{
+			FxGateway:    gw,
+			FunctionName: name,
+			Image:        snapshot.Image,
+			EnvVars:      snapshot.EnvVars,
+			Labels:       snapshot.Labels,
+			Annotations:  snapshot.Annotations,
+			Constraints:  snapshot.Constraints,
+			Secrets:      snapshot.Secrets,
+			Limits:       snapshot.Limits,
+			Requests:     snapshot.Requests,
+			MinReplicas:  snapshot.MinReplicas,
+			MaxReplicas:  snapshot.MaxReplicas,
+			Update:       true,
+			Replace:      false,
+		}
+
+		if err := deployFunc(rollbackConfig, token); err != nil {
+			errs = append(errs, fmt.Errorf("rollback %s: %w", name, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return combineErrors(errs)
+}
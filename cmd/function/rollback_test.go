@@ -0,0 +1,136 @@
+package function
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keti-openfx/openfx-cli/api/grpc"
+	"github.com/keti-openfx/openfx-cli/config"
+)
+
+func TestSnapshotFunctions(t *testing.T) {
+	orig := inspectFunc
+	defer func() { inspectFunc = orig }()
+
+	existing := &grpc.FunctionSpec{Image: "repo/fn:1"}
+
+	inspectFunc = func(gw, name, token string) (*grpc.FunctionSpec, error) {
+		switch name {
+		case "existing":
+			return existing, nil
+		case "new":
+			return nil, grpc.ErrFunctionNotFound
+		default:
+			return nil, errors.New("gateway unreachable")
+		}
+	}
+
+	t.Run("not-found becomes a nil snapshot", func(t *testing.T) {
+		snapshots, err := snapshotFunctions(map[string]config.Function{"new": {}})
+		if err != nil {
+			t.Fatalf("snapshotFunctions returned error: %v", err)
+		}
+		if snapshots["new"] != nil {
+			t.Errorf("snapshots[new] = %v, want nil", snapshots["new"])
+		}
+	})
+
+	t.Run("existing function is captured", func(t *testing.T) {
+		snapshots, err := snapshotFunctions(map[string]config.Function{"existing": {}})
+		if err != nil {
+			t.Fatalf("snapshotFunctions returned error: %v", err)
+		}
+		if snapshots["existing"] != existing {
+			t.Errorf("snapshots[existing] = %v, want %v", snapshots["existing"], existing)
+		}
+	})
+
+	t.Run("a real error aborts instead of being treated as not-found", func(t *testing.T) {
+		if _, err := snapshotFunctions(map[string]config.Function{"broken": {}}); err == nil {
+			t.Error("snapshotFunctions returned nil error for a transport failure, want error")
+		}
+	})
+}
+
+func TestRollbackFunctions(t *testing.T) {
+	origDeploy := deployFunc
+	origDelete := deleteFunc
+	defer func() {
+		deployFunc = origDeploy
+		deleteFunc = origDelete
+	}()
+
+	var deployedConfigs []grpc.DeployConfig
+	var deletedNames []string
+	deployFunc = func(cfg grpc.DeployConfig, token string) error {
+		deployedConfigs = append(deployedConfigs, cfg)
+		return nil
+	}
+	deleteFunc = func(gw, name, token string) error {
+		deletedNames = append(deletedNames, name)
+		return nil
+	}
+
+	snapshots := map[string]*grpc.FunctionSpec{
+		"existing": {
+			Image:       "repo/existing:1",
+			Constraints: []string{"node.labels.zone==a"},
+			Secrets:     []string{"regcred", "db-password"},
+			Limits:      &config.FunctionResources{Memory: "256Mi"},
+			Requests:    &config.FunctionResources{Memory: "128Mi"},
+		},
+		"new": nil,
+	}
+
+	err := rollbackFunctions("gateway:8080", []string{"existing", "new"}, snapshots, "token")
+	if err != nil {
+		t.Fatalf("rollbackFunctions() = %v, want nil", err)
+	}
+
+	if len(deployedConfigs) != 1 || deployedConfigs[0].FunctionName != "existing" {
+		t.Fatalf("deployed = %v, want redeploy of only [existing]", deployedConfigs)
+	}
+	if len(deletedNames) != 1 || deletedNames[0] != "new" {
+		t.Errorf("deleted = %v, want delete of only [new]", deletedNames)
+	}
+
+	restored := deployedConfigs[0]
+	if len(restored.Constraints) != 1 || restored.Constraints[0] != "node.labels.zone==a" {
+		t.Errorf("rollback Constraints = %v, want the snapshot's constraints restored", restored.Constraints)
+	}
+	if len(restored.Secrets) != 2 || restored.Secrets[1] != "db-password" {
+		t.Errorf("rollback Secrets = %v, want the snapshot's secrets restored", restored.Secrets)
+	}
+	if restored.Limits == nil || restored.Limits.Memory != "256Mi" {
+		t.Errorf("rollback Limits = %v, want the snapshot's limits restored", restored.Limits)
+	}
+	if restored.Requests == nil || restored.Requests.Memory != "128Mi" {
+		t.Errorf("rollback Requests = %v, want the snapshot's requests restored", restored.Requests)
+	}
+}
+
+func TestRollbackFunctionsCombinesErrors(t *testing.T) {
+	origDeploy := deployFunc
+	origDelete := deleteFunc
+	defer func() {
+		deployFunc = origDeploy
+		deleteFunc = origDelete
+	}()
+
+	deployFunc = func(cfg grpc.DeployConfig, token string) error {
+		return errors.New("gateway rejected redeploy")
+	}
+	deleteFunc = func(gw, name, token string) error {
+		return errors.New("gateway rejected delete")
+	}
+
+	snapshots := map[string]*grpc.FunctionSpec{
+		"existing": {Image: "repo/existing:1"},
+		"new":      nil,
+	}
+
+	err := rollbackFunctions("gateway:8080", []string{"existing", "new"}, snapshots, "token")
+	if err == nil {
+		t.Fatal("rollbackFunctions() = nil error, want an error combining both failures")
+	}
+}
@@ -0,0 +1,220 @@
+package function
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+)
+
+var (
+	setValues  []string
+	valuesFile string
+)
+
+func init() {
+	deployCmd.Flags().StringArrayVar(&setValues, "set", nil, "Set a template value (key=value) for rendering the config file; may be repeated")
+	deployCmd.Flags().StringVar(&valuesFile, "values", "", "Path to a YAML file supplying values for the config template")
+}
+
+// parseTemplatedConfigFile renders configFile as a Go text/template, with
+// values sourced from --values and --set (in that precedence order, "--set"
+// winning), before handing the result to the YAML parser. It then merges any
+// files named by a top-level "includes:" directive. This lets a single
+// config.yml be parameterized per environment (image tags, gateway URLs,
+// replica counts) instead of duplicated per deployment target.
+func parseTemplatedConfigFile() error {
+	values, err := loadTemplateValues()
+	if err != nil {
+		return err
+	}
+
+	rendered, err := renderConfigTemplate(configFile, values)
+	if err != nil {
+		return err
+	}
+
+	merged, err := mergeIncludes(rendered)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(merged, &fxServices)
+}
+
+func loadTemplateValues() (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	if valuesFile != "" {
+		bytesOut, err := ioutil.ReadFile(valuesFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(bytesOut, &values); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, set := range setValues {
+		parts := strings.SplitN(set, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf(`invalid --set value %q, expected "key=value"`, set)
+		}
+		setNestedValue(values, parts[0], parts[1])
+	}
+
+	return values, nil
+}
+
+// setNestedValue stores value in values under a dotted key path (e.g.
+// "replicas.max"), creating intermediate maps as needed, so that
+// "--set replicas.max=5" is reachable from a template as {{ .replicas.max }}
+// rather than only as the literal key {{ index . "replicas.max" }}.
+func setNestedValue(values map[string]interface{}, dottedKey, value string) {
+	parts := strings.Split(dottedKey, ".")
+
+	cursor := values
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := cursor[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			cursor[part] = next
+		}
+		cursor = next
+	}
+
+	cursor[parts[len(parts)-1]] = value
+}
+
+func renderConfigTemplate(path string, values map[string]interface{}) ([]byte, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(path).Funcs(template.FuncMap{
+		"env": os.Getenv,
+	}).Parse(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mergeIncludes merges the "functions" map of any files listed under a
+// top-level "includes:" directive into rendered, so a large deployment can
+// be split across files. A function named only in an include is added
+// as-is; a function named in both rendered and an include has its
+// per-function "environment" map merged by key (rendered's keys win on
+// conflict), so included env vars aren't lost just because the function
+// itself is also partially defined in the base file.
+func mergeIncludes(rendered []byte) ([]byte, error) {
+	root := map[string]interface{}{}
+	if err := yaml.Unmarshal(rendered, &root); err != nil {
+		return nil, err
+	}
+
+	includesRaw, ok := root["includes"]
+	if !ok {
+		return rendered, nil
+	}
+
+	includePaths, ok := toStringSlice(includesRaw)
+	if !ok {
+		return nil, fmt.Errorf(`"includes" must be a list of file paths`)
+	}
+	delete(root, "includes")
+
+	functions, _ := root["functions"].(map[string]interface{})
+	if functions == nil {
+		functions = map[string]interface{}{}
+	}
+
+	for _, includePath := range includePaths {
+		includeBytes, err := ioutil.ReadFile(includePath)
+		if err != nil {
+			return nil, err
+		}
+
+		includeRoot := map[string]interface{}{}
+		if err := yaml.Unmarshal(includeBytes, &includeRoot); err != nil {
+			return nil, err
+		}
+
+		if includeFunctions, ok := includeRoot["functions"].(map[string]interface{}); ok {
+			for name, fn := range includeFunctions {
+				existing, exists := functions[name]
+				if !exists {
+					functions[name] = fn
+					continue
+				}
+				mergeFunctionEnvironment(existing, fn)
+			}
+		}
+	}
+
+	root["functions"] = functions
+
+	return yaml.Marshal(root)
+}
+
+// mergeFunctionEnvironment merges include's "environment" map into base's,
+// key by key, without overwriting any key base already defines. base and
+// include are the raw map[string]interface{} decoded for one function name
+// present in both the rendered config and an include.
+func mergeFunctionEnvironment(base, include interface{}) {
+	baseFn, ok := base.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	includeFn, ok := include.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	includeEnv, ok := includeFn["environment"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	baseEnv, ok := baseFn["environment"].(map[string]interface{})
+	if !ok {
+		baseEnv = map[string]interface{}{}
+	}
+
+	for key, value := range includeEnv {
+		if _, exists := baseEnv[key]; !exists {
+			baseEnv[key] = value
+		}
+	}
+
+	baseFn["environment"] = baseEnv
+}
+
+func toStringSlice(v interface{}) ([]string, bool) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
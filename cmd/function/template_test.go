@@ -0,0 +1,122 @@
+package function
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func TestSetNestedValue(t *testing.T) {
+	values := map[string]interface{}{}
+
+	setNestedValue(values, "image", "repo/fn:1.2.3")
+	setNestedValue(values, "replicas.max", "5")
+	setNestedValue(values, "replicas.min", "1")
+
+	if values["image"] != "repo/fn:1.2.3" {
+		t.Errorf(`values["image"] = %v, want "repo/fn:1.2.3"`, values["image"])
+	}
+
+	replicas, ok := values["replicas"].(map[string]interface{})
+	if !ok {
+		t.Fatalf(`values["replicas"] = %v, want a nested map`, values["replicas"])
+	}
+	if replicas["max"] != "5" {
+		t.Errorf(`values["replicas"]["max"] = %v, want "5"`, replicas["max"])
+	}
+	if replicas["min"] != "1" {
+		t.Errorf(`values["replicas"]["min"] = %v, want "1"`, replicas["min"])
+	}
+}
+
+func TestMergeFunctionEnvironment(t *testing.T) {
+	base := map[string]interface{}{
+		"image": "repo/fn:1",
+		"environment": map[string]interface{}{
+			"FOO": "base",
+		},
+	}
+	include := map[string]interface{}{
+		"image": "repo/fn:ignored",
+		"environment": map[string]interface{}{
+			"FOO": "include",
+			"BAR": "include",
+		},
+	}
+
+	mergeFunctionEnvironment(base, include)
+
+	env := base["environment"].(map[string]interface{})
+	if env["FOO"] != "base" {
+		t.Errorf(`env["FOO"] = %v, want "base" (base wins on conflict)`, env["FOO"])
+	}
+	if env["BAR"] != "include" {
+		t.Errorf(`env["BAR"] = %v, want "include" (missing key added from include)`, env["BAR"])
+	}
+	if base["image"] != "repo/fn:1" {
+		t.Errorf(`base["image"] = %v, want unchanged "repo/fn:1"`, base["image"])
+	}
+}
+
+func TestMergeIncludes(t *testing.T) {
+	dir := t.TempDir()
+	includePath := dir + "/include.yml"
+
+	includeYAML := `
+functions:
+  fn-a:
+    image: repo/fn-a:1
+    environment:
+      SHARED: from-include
+      ONLY_INCLUDE: present
+  fn-b:
+    image: repo/fn-b:1
+`
+	if err := ioutil.WriteFile(includePath, []byte(includeYAML), 0644); err != nil {
+		t.Fatalf("WriteFile(%s) = %v", includePath, err)
+	}
+
+	rendered := []byte(`
+includes:
+  - ` + includePath + `
+functions:
+  fn-a:
+    image: repo/fn-a:2
+    environment:
+      SHARED: from-base
+`)
+
+	merged, err := mergeIncludes(rendered)
+	if err != nil {
+		t.Fatalf("mergeIncludes() = %v", err)
+	}
+
+	var services struct {
+		Functions map[string]struct {
+			Image       string            `yaml:"image"`
+			Environment map[string]string `yaml:"environment"`
+		} `yaml:"functions"`
+	}
+	if err := yaml.Unmarshal(merged, &services); err != nil {
+		t.Fatalf("unmarshal merged config: %v", err)
+	}
+
+	fnA, ok := services.Functions["fn-a"]
+	if !ok {
+		t.Fatal(`merged config is missing "fn-a"`)
+	}
+	if fnA.Image != "repo/fn-a:2" {
+		t.Errorf("fn-a image = %q, want base's %q to win", fnA.Image, "repo/fn-a:2")
+	}
+	if fnA.Environment["SHARED"] != "from-base" {
+		t.Errorf(`fn-a environment["SHARED"] = %q, want "from-base" (base wins on conflict)`, fnA.Environment["SHARED"])
+	}
+	if fnA.Environment["ONLY_INCLUDE"] != "present" {
+		t.Errorf(`fn-a environment["ONLY_INCLUDE"] = %q, want "present" (merged in from the include)`, fnA.Environment["ONLY_INCLUDE"])
+	}
+
+	if _, ok := services.Functions["fn-b"]; !ok {
+		t.Error(`merged config is missing "fn-b" (function only defined in the include)`)
+	}
+}
@@ -0,0 +1,9 @@
+// Package log prints CLI progress output.
+package log
+
+import "fmt"
+
+// Info prints a formatted progress line to stdout.
+func Info(format string, args ...interface{}) {
+	fmt.Printf(format, args...)
+}
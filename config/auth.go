@@ -0,0 +1,141 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// RegistryAuth overrides the stored registry credentials for a single
+// function via the "registryAuth" key in config.yml.
+type RegistryAuth struct {
+	Username   string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password   string `json:"password,omitempty" yaml:"password,omitempty"`
+	CredHelper string `json:"credHelper,omitempty" yaml:"credHelper,omitempty"`
+}
+
+type registryAuthEntry struct {
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	CredHelper string `json:"cred_helper,omitempty"`
+}
+
+type authEntry struct {
+	AccessToken string `json:"access_token,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Password    string `json:"password,omitempty"`
+}
+
+type storedConfig struct {
+	Auth       authEntry                    `json:"auth"`
+	Registries map[string]registryAuthEntry `json:"registries,omitempty"`
+}
+
+// defaultRegistryKey is the key under which credentials for the unqualified
+// (Docker Hub) registry are stored.
+const defaultRegistryKey = "docker.io"
+
+func configFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".openfx", "config.json"), nil
+}
+
+func readStoredConfig() (storedConfig, error) {
+	var cfg storedConfig
+
+	path, err := configFilePath()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func writeStoredConfig(cfg storedConfig) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// LookupAuthConfig returns the stored username, password and access token
+// used to authenticate CLI requests against the gateway.
+func LookupAuthConfig() (string, string, string, error) {
+	cfg, err := readStoredConfig()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return cfg.Auth.Username, cfg.Auth.Password, cfg.Auth.AccessToken, nil
+}
+
+func registryKey(registry string) string {
+	if registry == "" {
+		return defaultRegistryKey
+	}
+	return registry
+}
+
+// LookupRegistryAuth returns the credentials stored for the given registry
+// host ("" meaning Docker Hub) by "openfx-cli function login". It returns
+// zero values, not an error, when nothing has been stored yet.
+func LookupRegistryAuth(registry string) (username, secret, credHelper string, err error) {
+	cfg, err := readStoredConfig()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	entry, ok := cfg.Registries[registryKey(registry)]
+	if !ok {
+		return "", "", "", nil
+	}
+
+	return entry.Username, entry.Password, entry.CredHelper, nil
+}
+
+// SaveRegistryAuth persists registry credentials, or the name of a
+// credential helper, for use by subsequent "function deploy" runs.
+func SaveRegistryAuth(registry, username, secret, credHelper string) error {
+	cfg, err := readStoredConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Registries == nil {
+		cfg.Registries = map[string]registryAuthEntry{}
+	}
+
+	cfg.Registries[registryKey(registry)] = registryAuthEntry{
+		Username:   username,
+		Password:   secret,
+		CredHelper: credHelper,
+	}
+
+	return writeStoredConfig(cfg)
+}
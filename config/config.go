@@ -0,0 +1,54 @@
+// Package config loads the YAML deployment config and the persisted CLI
+// credentials used by the cmd/function commands.
+package config
+
+// Function describes a single function entry under the "functions:" key of
+// config.yml.
+type Function struct {
+	Name            string             `json:"-" yaml:"-"`
+	Image           string             `json:"image" yaml:"image"`
+	Environment     map[string]string  `json:"environment,omitempty" yaml:"environment,omitempty"`
+	EnvironmentFile []string           `json:"environment_file,omitempty" yaml:"environment_file,omitempty"`
+	Labels          *map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Maintainer      string             `json:"maintainer,omitempty" yaml:"maintainer,omitempty"`
+	Description     string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Constraints     []string           `json:"constraints,omitempty" yaml:"constraints,omitempty"`
+	Secrets         []string           `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	Limits          *FunctionResources `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Requests        *FunctionResources `json:"requests,omitempty" yaml:"requests,omitempty"`
+	RegistryURL     string             `json:"registry_url,omitempty" yaml:"registry_url,omitempty"`
+	RegistryAuth    *RegistryAuth      `json:"registryAuth,omitempty" yaml:"registryAuth,omitempty"`
+}
+
+// FunctionResources holds a CPU/memory limit or request pair.
+type FunctionResources struct {
+	CPU    string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// EnvironmentFile is the shape of a file named under a function's
+// "environment_file:" entries.
+type EnvironmentFile struct {
+	Environment map[string]string `json:"environment" yaml:"environment"`
+}
+
+// OpenfxConfig is the top-level "openfx:" block of config.yml.
+type OpenfxConfig struct {
+	FxGatewayURL string `json:"fx_gateway_url,omitempty" yaml:"fx_gateway_url,omitempty"`
+}
+
+// Services is the parsed shape of a whole config.yml: the gateway to target
+// plus every function to deploy against it.
+type Services struct {
+	Openfx    OpenfxConfig        `json:"openfx" yaml:"openfx"`
+	Functions map[string]Function `json:"functions" yaml:"functions"`
+}
+
+// GetFxGatewayURL prefers an explicitly supplied gateway (flagValue) over the
+// "openfx.fx_gateway_url" declared in config.yml.
+func GetFxGatewayURL(flagValue, configURL string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return configURL
+}